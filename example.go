@@ -2,28 +2,58 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/arm/disk"
 	"github.com/Azure/azure-sdk-for-go/arm/resources/resources"
 	"github.com/Azure/azure-sdk-for-go/arm/storage"
+	dataplane "github.com/Azure/azure-sdk-for-go/storage"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/adal"
 	"github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/to"
 )
 
 const (
-	location    = "westus"
+	containerName = "getting-started"
+	blobName      = "hello-world.txt"
+	provider      = "Microsoft.Storage"
+
+	diskName          = "golangrocksonazuredisk"
+	snapshotName      = "golangrocksonazuresnapshot"
+	diskSizeGB        = 10
+	snapshotAccessTTL = 3600 // seconds the generated snapshot SAS URI stays valid
+)
+
+var (
 	groupName   = "your-azure-sample-group"
 	accountName = "golangrocksonazure"
-	provider    = "Microsoft.Storage"
+	location    = "westus"
 )
 
 // This example requires that the following environment vars are set:
 //
 // AZURE_TENANT_ID: contains your Azure Active Directory tenant ID or domain
-// AZURE_CLIENT_ID: contains your Azure Active Directory Application Client ID
-// AZURE_CLIENT_SECRET: contains your Azure Active Directory Application Secret
 // AZURE_SUBSCRIPTION_ID: contains your Azure Subscription ID
+// AZURE_ENVIRONMENT: (optional) AzurePublicCloud (default), AzureChinaCloud, AzureGermanCloud or AzureUSGovernmentCloud
+// AZURE_ENVIRONMENT_FILEPATH: (optional) path to a custom environment JSON file, takes precedence over AZURE_ENVIRONMENT
+// AZURE_AUTH_METHOD: (optional) client_secret (default), client_certificate, msi or cli
+//
+// client_secret also requires AZURE_CLIENT_ID and AZURE_CLIENT_SECRET
+// client_certificate also requires AZURE_CLIENT_ID and AZURE_CERTIFICATE_PATH, and reads AZURE_CERTIFICATE_PASSWORD if it is set
+// msi optionally reads AZURE_MSI_ENDPOINT and does not read AZURE_TENANT_ID
+// cli does not read AZURE_TENANT_ID either; it reuses the token cached by `az login`
 //
 
 var (
@@ -31,43 +61,346 @@ var (
 	storageClient   storage.AccountsClient
 	groupClient     resources.GroupsClient
 	usageClient     storage.UsageOperationsClient
+	diskClient      disk.DisksClient
+	snapshotClient  disk.SnapshotsClient
 )
 
 func init() {
 	subscriptionID := getEnvVarOrExit("AZURE_SUBSCRIPTION_ID")
-	tenantID := getEnvVarOrExit("AZURE_TENANT_ID")
 
-	oauthConfig, err := azure.PublicCloud.OAuthConfigForTenant(tenantID)
-	onErrorFail(err, "OAuthConfigForTenant failed")
+	env, err := getAzureEnvironment()
+	onErrorFail(err, "getAzureEnvironment failed")
+
+	authorizer, err := getAuthorizer(env)
+	onErrorFail(err, "getAuthorizer failed")
+
+	createClients(subscriptionID, authorizer)
+}
+
+// getAzureEnvironment returns the Azure environment named by AZURE_ENVIRONMENT_FILEPATH or AZURE_ENVIRONMENT, defaulting to AzurePublicCloud.
+func getAzureEnvironment() (azure.Environment, error) {
+	if filepath := os.Getenv("AZURE_ENVIRONMENT_FILEPATH"); filepath != "" {
+		return azure.EnvironmentFromFile(filepath)
+	}
+
+	name := os.Getenv("AZURE_ENVIRONMENT")
+	if name == "" {
+		return azure.PublicCloud, nil
+	}
 
+	return azure.EnvironmentFromName(name)
+}
+
+// getAuthorizer returns an authorizer for AZURE_AUTH_METHOD, defaulting to the client-secret flow.
+func getAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	switch strings.ToLower(os.Getenv("AZURE_AUTH_METHOD")) {
+	case "msi":
+		return getMSIAuthorizer(env)
+	case "client_certificate":
+		return getClientCertificateAuthorizer(env)
+	case "cli":
+		return getCLIAuthorizer(env)
+	default:
+		return getClientSecretAuthorizer(env)
+	}
+}
+
+func getClientSecretAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	tenantID := getEnvVarOrExit("AZURE_TENANT_ID")
 	clientID := getEnvVarOrExit("AZURE_CLIENT_ID")
 	clientSecret := getEnvVarOrExit("AZURE_CLIENT_SECRET")
-	spToken, err := azure.NewServicePrincipalToken(*oauthConfig, clientID, clientSecret, azure.PublicCloud.ResourceManagerEndpoint)
-	onErrorFail(err, "NewServicePrincipalToken failed")
 
-	createClients(subscriptionID, spToken)
+	oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	spToken, err := adal.NewServicePrincipalToken(*oauthConfig, clientID, clientSecret, env.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return autorest.NewBearerAuthorizer(spToken), nil
+}
+
+func getClientCertificateAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	tenantID := getEnvVarOrExit("AZURE_TENANT_ID")
+	clientID := getEnvVarOrExit("AZURE_CLIENT_ID")
+	certPath := getEnvVarOrExit("AZURE_CERTIFICATE_PATH")
+	certPassword := os.Getenv("AZURE_CERTIFICATE_PASSWORD")
+
+	pfxData, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, privateKey, err := adal.DecodePfxCertificateData(pfxData, certPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	oauthConfig, err := adal.NewOAuthConfig(env.ActiveDirectoryEndpoint, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	spToken, err := adal.NewServicePrincipalTokenFromCertificate(*oauthConfig, clientID, certificate, privateKey, env.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return autorest.NewBearerAuthorizer(spToken), nil
+}
+
+func getMSIAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	msiEndpoint := os.Getenv("AZURE_MSI_ENDPOINT")
+	if msiEndpoint == "" {
+		var err error
+		msiEndpoint, err = adal.GetMSIVMEndpoint()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	spToken, err := adal.NewServicePrincipalTokenFromMSI(msiEndpoint, env.ResourceManagerEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return autorest.NewBearerAuthorizer(spToken), nil
+}
+
+func getCLIAuthorizer(env azure.Environment) (autorest.Authorizer, error) {
+	return cliAuthorizer{resource: env.ResourceManagerEndpoint}, nil
+}
+
+// cliAuthorizer asks the Azure CLI for a fresh access token on every request.
+type cliAuthorizer struct {
+	resource string
+}
+
+func (c cliAuthorizer) WithAuthorization() autorest.PrepareDecorator {
+	return func(p autorest.Preparer) autorest.Preparer {
+		return autorest.PreparerFunc(func(r *http.Request) (*http.Request, error) {
+			r, err := p.Prepare(r)
+			if err != nil {
+				return r, err
+			}
+
+			token, err := getCLIAccessToken(c.resource)
+			if err != nil {
+				return r, err
+			}
+
+			return autorest.Prepare(r, autorest.WithHeader("Authorization", "Bearer "+token))
+		})
+	}
+}
+
+// getCLIAccessToken returns the access token `az login` cached for resource.
+func getCLIAccessToken(resource string) (string, error) {
+	out, err := exec.Command("az", "account", "get-access-token", "--resource", resource, "-o", "json").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to invoke az cli: %s", err)
+	}
+
+	var cliToken struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err := json.Unmarshal(out, &cliToken); err != nil {
+		return "", fmt.Errorf("failed to parse az cli output: %s", err)
+	}
+
+	return cliToken.AccessToken, nil
 }
 
 func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	cmd, args := os.Args[1], os.Args[2:]
+
+	switch cmd {
+	case "check-name":
+		runCheckName(args)
+	case "create-account":
+		runCreateAccount(args)
+	case "list":
+		runList(args)
+	case "show":
+		runShow(args)
+	case "keys":
+		runKeys(args)
+	case "update-tags":
+		runUpdateTags(args)
+	case "usage":
+		runUsage(args)
+	case "blob-demo":
+		runBlobDemo(args)
+	case "disk-demo":
+		runDiskDemo(args)
+	case "delete":
+		runDelete(args)
+	case "-h", "--help", "help":
+		printUsage()
+	default:
+		fmt.Printf("Unknown command %q\n\n", cmd)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println(`Usage: example <command> [flags]
+
+Commands:
+  check-name       check whether --account is available
+  create-account   create --resource-group and --account
+  list             list storage accounts in --resource-group and the subscription
+  show             show properties of --account
+  keys regenerate  regenerate the primary key of --account
+  update-tags      update tags on --account
+  usage            list storage account usage for the subscription
+  blob-demo        upload/list/download a blob in --account
+  disk-demo        snapshot a managed disk in --resource-group and grant/revoke SAS access
+  delete           delete --account and --resource-group
+
+Run '<command> -h' to see the flags each command accepts.`)
+}
+
+func commonFlags(fs *flag.FlagSet) {
+	fs.StringVar(&groupName, "resource-group", groupName, "name of the resource group")
+	fs.StringVar(&accountName, "account", accountName, "name of the storage account")
+	fs.StringVar(&location, "location", location, "Azure region, e.g. westus")
+}
+
+func timeoutFlag(fs *flag.FlagSet) *time.Duration {
+	return fs.Duration("timeout", 10*time.Minute, "maximum time to wait for a long-running operation before aborting it")
+}
+
+// contextWithInterrupt cancels the returned context on timeout or Ctrl-C.
+func contextWithInterrupt(timeout time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt)
+	go func() {
+		select {
+		case <-sigCh:
+			fmt.Println("\nInterrupted, canceling in-flight operations...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
+
+func runCheckName(args []string) {
+	fs := flag.NewFlagSet("check-name", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
+	checkAccountAvailability()
+}
+
+func runCreateAccount(args []string) {
+	fs := flag.NewFlagSet("create-account", flag.ExitOnError)
+	commonFlags(fs)
+	sku := fs.String("sku", string(storage.StandardLRS),
+		"SKU name: Standard_LRS, Standard_GRS, Standard_RAGRS, Standard_ZRS, Premium_LRS")
+	kind := fs.String("kind", string(storage.Storage), "account kind: Storage, StorageV2, BlobStorage")
+	accessTier := fs.String("access-tier", "", "access tier for BlobStorage/StorageV2 accounts: Hot or Cool")
+	timeout := timeoutFlag(fs)
+	fs.Parse(args)
+
+	ctx, cancel := contextWithInterrupt(*timeout)
+	defer cancel()
+
 	registerResourceProvider()
 	checkAccountAvailability()
 	createResourceGroup()
-	createStorageAccount()
+	createStorageAccount(ctx, storage.SkuName(*sku), storage.Kind(*kind), storage.AccessTier(*accessTier))
 	getStorageAccountProperties()
+	blobWalkthrough(getStorageKeys())
+	diskAndSnapshotWalkthrough()
+}
+
+func runBlobDemo(args []string) {
+	fs := flag.NewFlagSet("blob-demo", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
+	blobWalkthrough(getStorageKeys())
+}
+
+func runDiskDemo(args []string) {
+	fs := flag.NewFlagSet("disk-demo", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
+	diskAndSnapshotWalkthrough()
+}
+
+func runList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
 	listStorageAccountsByResourceGroup()
 	listStorageAccountsBySubscription()
+}
+
+func runShow(args []string) {
+	fs := flag.NewFlagSet("show", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
+	getStorageAccountProperties()
+}
+
+func runKeys(args []string) {
+	if len(args) == 0 || args[0] != "regenerate" {
+		fmt.Println(`Usage: example keys regenerate [flags]`)
+		os.Exit(1)
+	}
+
+	fs := flag.NewFlagSet("keys regenerate", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args[1:])
+
 	keys := getStorageKeys()
 	regenStorageKey(keys)
+}
+
+func runUpdateTags(args []string) {
+	fs := flag.NewFlagSet("update-tags", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
 	updateStorageAccount()
+}
+
+func runUsage(args []string) {
+	fs := flag.NewFlagSet("usage", flag.ExitOnError)
+	commonFlags(fs)
+	fs.Parse(args)
+
 	listUsage()
+}
 
-	fmt.Print(fmt.Sprintf("Press enter to delete the resource group '%s'... (y/n)", groupName))
+func runDelete(args []string) {
+	fs := flag.NewFlagSet("delete", flag.ExitOnError)
+	commonFlags(fs)
+	timeout := timeoutFlag(fs)
+	fs.Parse(args)
 
-	var input string
-	fmt.Scanln(&input)
-	if input == "y" {
-		delete()
-	}
+	ctx, cancel := contextWithInterrupt(*timeout)
+	defer cancel()
+
+	delete(ctx)
 }
 
 // getEnvVarOrExit returns the value of specified environment variable or terminates if it's not defined.
@@ -81,18 +414,24 @@ func getEnvVarOrExit(varName string) string {
 	return value
 }
 
-func createClients(subscriptionID string, spToken *azure.ServicePrincipalToken) {
+func createClients(subscriptionID string, authorizer autorest.Authorizer) {
 	resourcesClient = resources.NewProvidersClient(subscriptionID)
-	resourcesClient.Authorizer = spToken
+	resourcesClient.Authorizer = authorizer
 
 	storageClient = storage.NewAccountsClient(subscriptionID)
-	storageClient.Authorizer = spToken
+	storageClient.Authorizer = authorizer
 
 	groupClient = resources.NewGroupsClient(subscriptionID)
-	groupClient.Authorizer = spToken
+	groupClient.Authorizer = authorizer
 
 	usageClient = storage.NewUsageOperationsClient(subscriptionID)
-	usageClient.Authorizer = spToken
+	usageClient.Authorizer = authorizer
+
+	diskClient = disk.NewDisksClient(subscriptionID)
+	diskClient.Authorizer = authorizer
+
+	snapshotClient = disk.NewSnapshotsClient(subscriptionID)
+	snapshotClient.Authorizer = authorizer
 }
 
 func registerResourceProvider() {
@@ -129,18 +468,74 @@ func createResourceGroup() {
 	onErrorFail(err, "CreateOrUpdate failed")
 }
 
-func createStorageAccount() {
+func createStorageAccount(ctx context.Context, sku storage.SkuName, kind storage.Kind, accessTier storage.AccessTier) {
 	fmt.Println("Create storage account...")
-	_, err := storageClient.Create(groupName, accountName, storage.AccountCreateParameters{
+
+	properties := &storage.AccountPropertiesCreateParameters{}
+	if accessTier != "" {
+		properties.AccessTier = accessTier
+	}
+
+	params := storage.AccountCreateParameters{
 		Sku: &storage.Sku{
-			Name: storage.StandardLRS,
+			Name: sku,
 		},
-		Location: to.StringPtr(location),
-		AccountPropertiesCreateParameters: &storage.AccountPropertiesCreateParameters{},
-	}, nil)
+	}
+	params.Kind = kind
+	params.Location = to.StringPtr(location)
+	params.AccountPropertiesCreateParameters = properties
+
+	err := withWatcher(ctx, "create storage account", func(cancel <-chan struct{}) error {
+		_, err := storageClient.Create(groupName, accountName, params, cancel)
+		return err
+	}, func() (string, error) {
+		account, err := storageClient.GetProperties(groupName, accountName)
+		if err != nil {
+			return "", err
+		}
+		return string(account.ProvisioningState), nil
+	})
 	onErrorFail(err, "Create failed")
 }
 
+// withWatcher runs do in the background, printing progress until it finishes or ctx is done.
+func withWatcher(ctx context.Context, operation string, do func(cancel <-chan struct{}) error, poll func() (string, error)) error {
+	cancelCh := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(cancelCh)
+	}()
+
+	resultCh := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		resultCh <- do(cancelCh)
+	}()
+
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	attempt := 0
+	for {
+		select {
+		case err := <-resultCh:
+			return err
+		case <-ticker.C:
+			attempt++
+			state := "unknown"
+			if poll != nil {
+				if s, err := poll(); err == nil {
+					state = s
+				}
+			}
+			fmt.Printf("\t...polling %s: attempt %d, elapsed %s, provisioning state: %s\n",
+				operation, attempt, time.Since(start).Round(time.Second), state)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 func getStorageAccountProperties() {
 	fmt.Println("Get storage account properties...")
 	account, err := storageClient.GetProperties(groupName, accountName)
@@ -191,6 +586,46 @@ func getStorageKeys() *storage.AccountListKeysResult {
 	return &keys
 }
 
+// blobWalkthrough uploads, lists and downloads a blob, then cleans up the container.
+func blobWalkthrough(keys *storage.AccountListKeysResult) {
+	fmt.Println("Blob upload/download walkthrough...")
+	accountKey := *(*keys.Keys)[0].Value
+
+	client, err := dataplane.NewBasicClient(accountName, accountKey)
+	onErrorFail(err, "NewBasicClient failed")
+
+	blobService := client.GetBlobService()
+	container := blobService.GetContainerReference(containerName)
+
+	_, err = container.CreateIfNotExists(nil)
+	onErrorFail(err, "CreateIfNotExists failed")
+
+	fmt.Printf("\tUploading blob '%s' to container '%s'...\n", blobName, containerName)
+	blob := container.GetBlobReference(blobName)
+	err = blob.CreateBlockBlobFromReader(bytes.NewReader([]byte("hello world from the Go storage sample!")), nil)
+	onErrorFail(err, "CreateBlockBlobFromReader failed")
+
+	fmt.Printf("\tListing blobs in container '%s'...\n", containerName)
+	list, err := container.ListBlobs(dataplane.ListBlobsParameters{})
+	onErrorFail(err, "ListBlobs failed")
+	for _, b := range list.Blobs {
+		fmt.Printf("\t\t%s\n", b.Name)
+	}
+
+	fmt.Printf("\tDownloading blob '%s'...\n", blobName)
+	reader, err := blob.Get(nil)
+	onErrorFail(err, "Get failed")
+	defer reader.Close()
+
+	content, err := ioutil.ReadAll(reader)
+	onErrorFail(err, "ReadAll failed")
+	fmt.Printf("\t\tContent: %s\n", content)
+
+	fmt.Printf("\tDeleting container '%s'...\n", containerName)
+	_, err = container.DeleteIfExists(nil)
+	onErrorFail(err, "DeleteIfExists failed")
+}
+
 func regenStorageKey(keys *storage.AccountListKeysResult) {
 	fmt.Println("Regenerate account key...")
 	newKeys, err := storageClient.RegenerateKey(groupName, accountName, storage.AccountRegenerateKeyParameters{
@@ -225,13 +660,101 @@ func listUsage() {
 	}
 }
 
-func delete() {
+// diskAndSnapshotWalkthrough creates a disk, snapshots it, grants a SAS URI to the snapshot, then tears both down.
+func diskAndSnapshotWalkthrough() {
+	createManagedDisk()
+	createSnapshot()
+	listSnapshots()
+
+	sasURI := grantSnapshotAccess()
+	fmt.Printf("\tSnapshot SAS URI (valid for %d seconds): %s\n", snapshotAccessTTL, sasURI)
+	revokeSnapshotAccess()
+
+	deleteSnapshot()
+	deleteManagedDisk()
+}
+
+func createManagedDisk() {
+	fmt.Println("Create managed disk...")
+	_, err := diskClient.CreateOrUpdate(groupName, diskName, disk.Model{
+		Location: to.StringPtr(location),
+		DiskProperties: &disk.Properties{
+			CreationData: &disk.CreationData{
+				CreateOption: disk.Empty,
+			},
+			DiskSizeGB: to.Int32Ptr(diskSizeGB),
+		},
+	}, nil)
+	onErrorFail(err, "CreateOrUpdate failed")
+}
+
+func createSnapshot() {
+	fmt.Println("Create snapshot of managed disk...")
+	managedDisk, err := diskClient.Get(groupName, diskName)
+	onErrorFail(err, "Get failed")
+
+	_, err = snapshotClient.CreateOrUpdate(groupName, snapshotName, disk.Snapshot{
+		Location: to.StringPtr(location),
+		SnapshotProperties: &disk.SnapshotProperties{
+			CreationData: &disk.CreationData{
+				CreateOption:     disk.Copy,
+				SourceResourceID: managedDisk.ID,
+			},
+		},
+	}, nil)
+	onErrorFail(err, "CreateOrUpdate failed")
+}
+
+func listSnapshots() {
+	fmt.Printf("List all snapshots in '%s' resource group\n", groupName)
+	list, err := snapshotClient.ListByResourceGroup(groupName)
+	onErrorFail(err, "ListByResourceGroup failed")
+
+	for _, snap := range *list.Value {
+		fmt.Printf("\t%s\n", *snap.Name)
+	}
+}
+
+// grantSnapshotAccess returns a read-only SAS URI for the snapshot.
+func grantSnapshotAccess() string {
+	fmt.Println("Grant access to snapshot...")
+	result, err := snapshotClient.GrantAccess(groupName, snapshotName, disk.GrantAccessData{
+		Access:            disk.Read,
+		DurationInSeconds: to.Int32Ptr(snapshotAccessTTL),
+	}, nil)
+	onErrorFail(err, "GrantAccess failed")
+
+	return *result.AccessSAS
+}
+
+func revokeSnapshotAccess() {
+	fmt.Println("Revoke access to snapshot...")
+	_, err := snapshotClient.RevokeAccess(groupName, snapshotName, nil)
+	onErrorFail(err, "RevokeAccess failed")
+}
+
+func deleteSnapshot() {
+	fmt.Println("Delete snapshot...")
+	_, err := snapshotClient.Delete(groupName, snapshotName, nil)
+	onErrorFail(err, "Delete failed")
+}
+
+func deleteManagedDisk() {
+	fmt.Println("Delete managed disk...")
+	_, err := diskClient.Delete(groupName, diskName, nil)
+	onErrorFail(err, "Delete failed")
+}
+
+func delete(ctx context.Context) {
 	fmt.Println("Delete storage account...")
 	_, err := storageClient.Delete(groupName, accountName)
 	onErrorFail(err, "Delete failed")
 
 	fmt.Println("Delete resource group...")
-	_, err = groupClient.Delete(groupName, nil)
+	err = withWatcher(ctx, "delete resource group", func(cancel <-chan struct{}) error {
+		_, err := groupClient.Delete(groupName, cancel)
+		return err
+	}, nil)
 	onErrorFail(err, "Delete failed")
 }
 